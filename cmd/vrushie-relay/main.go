@@ -0,0 +1,201 @@
+// Command vrushie-relay is the companion server for `vrushie --public`. It
+// terminates TLS for both vrushie clients and the recipients they're
+// sharing with, hands out a short session token per client, and reverse
+// proxies each public request to the correct client over a yamux session.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/yamux"
+)
+
+var (
+	controlAddr = flag.String("control-addr", ":9090", "Address to accept vrushie --public client connections on")
+	httpAddr    = flag.String("http-addr", ":8443", "Address to serve public HTTP(S) requests on")
+	certFile    = flag.String("cert", "", "TLS certificate file (PEM, chain-aware)")
+	keyFile     = flag.String("key", "", "TLS private key file (PEM)")
+)
+
+// registry tracks the yamux session each live client registered under its token.
+type registry struct {
+	mu       sync.RWMutex
+	sessions map[string]*yamux.Session
+}
+
+func newRegistry() *registry {
+	return &registry{sessions: make(map[string]*yamux.Session)}
+}
+
+func (r *registry) add(token string, session *yamux.Session) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[token] = session
+}
+
+func (r *registry) remove(token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, token)
+}
+
+func (r *registry) get(token string) (*yamux.Session, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.sessions[token]
+	return s, ok
+}
+
+// newToken generates a short, URL-safe session token for a newly connected client.
+func newToken() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// acceptClients listens for incoming vrushie --public connections, hands
+// each one a token, and starts a yamux server session on the connection.
+func acceptClients(listener net.Listener, reg *registry) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("control listener accept error: %v", err)
+			continue
+		}
+		go handleClient(conn, reg)
+	}
+}
+
+func handleClient(conn net.Conn, reg *registry) {
+	token, err := newToken()
+	if err != nil {
+		log.Printf("failed to generate token: %v", err)
+		conn.Close()
+		return
+	}
+	if _, err := fmt.Fprintf(conn, "TOKEN %s\n", token); err != nil {
+		log.Printf("failed to send token to client: %v", err)
+		conn.Close()
+		return
+	}
+
+	session, err := yamux.Server(conn, yamux.DefaultConfig())
+	if err != nil {
+		log.Printf("failed to start yamux session for %s: %v", token, err)
+		conn.Close()
+		return
+	}
+
+	reg.add(token, session)
+	log.Printf("client registered: token=%s", token)
+
+	// Clean up once the client disconnects.
+	<-session.CloseChan()
+	reg.remove(token)
+	log.Printf("client disconnected: token=%s", token)
+}
+
+// publicHandler dispatches each incoming /s/<token>/... request to the
+// matching client session, stripping the token prefix and injecting the
+// real client IP the same way any reverse proxy would.
+func publicHandler(reg *registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, rest, ok := parseTokenPath(r.URL.Path)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		session, ok := reg.get(token)
+		if !ok {
+			http.Error(w, "unknown or expired share", http.StatusNotFound)
+			return
+		}
+
+		clientIP := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			clientIP = host
+		}
+
+		proxy := &httputil.ReverseProxy{
+			Director: func(req *http.Request) {
+				req.URL.Scheme = "http"
+				req.URL.Host = "yamux"
+				req.URL.Path = rest
+				req.Header.Set("X-Forwarded-For", clientIP)
+			},
+			Transport: &http.Transport{
+				DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+					return session.Open()
+				},
+			},
+		}
+		proxy.ServeHTTP(w, r)
+	}
+}
+
+// parseTokenPath splits "/s/<token>/rest/of/path" into its token and the
+// path to forward to the client's own mux (defaulting to "/").
+func parseTokenPath(path string) (token, rest string, ok bool) {
+	const prefix = "/s/"
+	if !strings.HasPrefix(path, prefix) {
+		return "", "", false
+	}
+	trimmed := strings.TrimPrefix(path, prefix)
+	parts := strings.SplitN(trimmed, "/", 2)
+	if parts[0] == "" {
+		return "", "", false
+	}
+	rest = "/"
+	if len(parts) == 2 && parts[1] != "" {
+		rest = "/" + parts[1]
+	}
+	return parts[0], rest, true
+}
+
+func main() {
+	flag.Parse()
+
+	if *certFile == "" || *keyFile == "" {
+		log.Fatal("vrushie-relay requires -cert and -key")
+	}
+	cert, err := tls.LoadX509KeyPair(*certFile, *keyFile)
+	if err != nil {
+		log.Fatalf("failed to load TLS certificate: %v", err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	reg := newRegistry()
+
+	controlListener, err := tls.Listen("tcp", *controlAddr, tlsConfig)
+	if err != nil {
+		log.Fatalf("failed to listen for clients on %s: %v", *controlAddr, err)
+	}
+	go acceptClients(controlListener, reg)
+	log.Printf("accepting vrushie --public clients on %s", *controlAddr)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/s/", publicHandler(reg))
+	httpServer := &http.Server{
+		Addr:      *httpAddr,
+		Handler:   mux,
+		TLSConfig: tlsConfig,
+	}
+	log.Printf("serving public requests on %s", *httpAddr)
+	if err := httpServer.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("relay HTTP server failed: %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestParseTokenPath(t *testing.T) {
+	cases := []struct {
+		name      string
+		path      string
+		wantToken string
+		wantRest  string
+		wantOK    bool
+	}{
+		{"bare token", "/s/abc123", "abc123", "/", true},
+		{"bare token with trailing slash", "/s/abc123/", "abc123", "/", true},
+		{"token with rest path", "/s/abc123/photo.jpg", "abc123", "/photo.jpg", true},
+		{"token with nested rest path", "/s/abc123/dir/photo.jpg", "abc123", "/dir/photo.jpg", true},
+		{"missing prefix", "/other/abc123", "", "", false},
+		{"missing token", "/s/", "", "", false},
+		{"empty path", "", "", "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			token, rest, ok := parseTokenPath(c.path)
+			if ok != c.wantOK || token != c.wantToken || rest != c.wantRest {
+				t.Errorf("parseTokenPath(%q) = (%q, %q, %v), want (%q, %q, %v)",
+					c.path, token, rest, ok, c.wantToken, c.wantRest, c.wantOK)
+			}
+		})
+	}
+}
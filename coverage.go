@@ -0,0 +1,43 @@
+package main
+
+import "sort"
+
+// byteRange is an inclusive [start, end] span of bytes already served to a
+// given client, used to tell whether a resumable download (many small
+// Range requests) has actually delivered the whole file yet.
+type byteRange struct {
+	start, end int64
+}
+
+// mergeByteRange inserts r into ranges and coalesces any spans it connects
+// or overlaps with, keeping the slice sorted and non-overlapping.
+func mergeByteRange(ranges []byteRange, r byteRange) []byteRange {
+	ranges = append(ranges, r)
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+
+	merged := ranges[:1]
+	for _, next := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if next.start <= last.end+1 {
+			if next.end > last.end {
+				last.end = next.end
+			}
+			continue
+		}
+		merged = append(merged, next)
+	}
+	return merged
+}
+
+// rangeCoversAll reports whether ranges, as kept by mergeByteRange, fully
+// cover [0, total). It only accepts a single span: a slice holding more than
+// one span is reported as not covering, even if its spans are adjacent.
+func rangeCoversAll(ranges []byteRange, total int64) bool {
+	if total <= 0 {
+		return false
+	}
+	if len(ranges) != 1 {
+		return false
+	}
+	return ranges[0].start <= 0 && ranges[0].end >= total-1
+}
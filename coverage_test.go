@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMergeByteRange(t *testing.T) {
+	cases := []struct {
+		name string
+		in   []byteRange
+		add  byteRange
+		want []byteRange
+	}{
+		{"first range", nil, byteRange{0, 9}, []byteRange{{0, 9}}},
+		{"adjacent extends", []byteRange{{0, 9}}, byteRange{10, 19}, []byteRange{{0, 19}}},
+		{"overlapping extends", []byteRange{{0, 9}}, byteRange{5, 19}, []byteRange{{0, 19}}},
+		{"disjoint stays separate", []byteRange{{0, 9}}, byteRange{20, 29}, []byteRange{{0, 9}, {20, 29}}},
+		{"fills the gap", []byteRange{{0, 9}, {20, 29}}, byteRange{10, 19}, []byteRange{{0, 29}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := mergeByteRange(c.in, c.add)
+			if len(got) != len(c.want) {
+				t.Fatalf("mergeByteRange() = %v, want %v", got, c.want)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Fatalf("mergeByteRange() = %v, want %v", got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRangeCoversAll(t *testing.T) {
+	cases := []struct {
+		name   string
+		ranges []byteRange
+		total  int64
+		want   bool
+	}{
+		{"empty total", nil, 0, false},
+		{"no ranges", nil, 100, false},
+		{"single full range", []byteRange{{0, 99}}, 100, true},
+		{"single partial range", []byteRange{{0, 49}}, 100, false},
+		{"multiple unmerged ranges never count as complete", []byteRange{{0, 49}, {50, 99}}, 100, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := rangeCoversAll(c.ranges, c.total); got != c.want {
+				t.Errorf("rangeCoversAll(%v, %d) = %v, want %v", c.ranges, c.total, got, c.want)
+			}
+		})
+	}
+}
+
+func TestServedByteRange(t *testing.T) {
+	t.Run("plain request covers the whole file", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		headers := http.Header{}
+		got, ok := servedByteRange(r, headers, 100, 100)
+		if !ok || got != (byteRange{0, 99}) {
+			t.Errorf("servedByteRange() = %v, %v, want {0 99}, true", got, ok)
+		}
+	})
+
+	t.Run("single range request reports the served span", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Range", "bytes=10-19")
+		headers := http.Header{}
+		headers.Set("Content-Range", "bytes 10-19/100")
+		got, ok := servedByteRange(r, headers, 10, 100)
+		if !ok || got != (byteRange{10, 19}) {
+			t.Errorf("servedByteRange() = %v, %v, want {10 19}, true", got, ok)
+		}
+	})
+
+	t.Run("multi-range request is not trackable", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Range", "bytes=0-10,20-30")
+		headers := http.Header{}
+		headers.Set("Content-Type", "multipart/byteranges; boundary=SEPARATOR")
+		_, ok := servedByteRange(r, headers, 500, 100)
+		if ok {
+			t.Errorf("servedByteRange() on a multipart/byteranges response should not be trackable")
+		}
+	})
+}
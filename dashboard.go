@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorilla/websocket"
+)
+
+// --- Live web dashboard ---
+//
+// --dashboard mounts a second, independent HTTP server (loopback-only
+// unless --dashboard-addr says otherwise) so a user can leave vrushie
+// running and watch transfers from a browser tab instead of the terminal.
+// New activity fans out to every open /ws connection the same way a
+// supervisor or log-tail server would: one channel per subscriber,
+// registered with the producer, drained with `for msg := range ch`.
+//
+// --dashboard-addr can be pointed at a non-loopback interface, at which
+// point any page a viewer's browser has open could otherwise open a
+// cross-origin WS to /ws or POST to /api/revoke. Every dashboard request
+// (page, /ws, /api/revoke) is gated on a random per-run token instead of
+// relying on CheckOrigin, so only someone with the dashboard URL shown in
+// the TUI can use it.
+
+var dashboardUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true }, // auth is via dashboardToken, not Origin
+}
+
+// newDashboardToken generates a short, URL-safe token gating dashboard
+// access, the same way newToken in cmd/vrushie-relay mints session tokens.
+func newDashboardToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// dashboardAuthorized checks the request's token against m.dashboardToken
+// using a constant-time comparison, checking both the query string (used by
+// the WS upgrade, which can't set custom headers from the browser) and the
+// X-Dashboard-Token header (used by the page's own fetch() calls).
+func (m *model) dashboardAuthorized(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = r.Header.Get("X-Dashboard-Token")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(m.dashboardToken)) == 1
+}
+
+// dashboardReadyMsg reports the address the dashboard ended up bound to.
+type dashboardReadyMsg struct{ url string }
+
+// dashboardFrame is the JSON shape sent down /ws: either the initial
+// snapshot or a single new activity entry.
+type dashboardFrame struct {
+	Type          string           `json:"type"` // "init" or "activity"
+	Activity      []dashboardEntry `json:"activity,omitempty"`
+	Entry         *dashboardEntry  `json:"entry,omitempty"`
+	DownloadCount int              `json:"downloadCount"`
+	LimitN        int              `json:"limitN"`
+	AllowedIPs    []string         `json:"allowedIPs"`
+}
+
+type dashboardEntry struct {
+	Timestamp string `json:"timestamp"`
+	IP        string `json:"ip"`
+	Action    string `json:"action"`
+}
+
+func toDashboardEntry(a activityLog) dashboardEntry {
+	return dashboardEntry{Timestamp: a.Timestamp.Format("15:04:05"), IP: a.IP, Action: a.Action}
+}
+
+// logActivity records an activity entry both for the TUI (via
+// activityChan, as before) and for the dashboard, fanning it out to every
+// /ws subscriber. It keeps its own mirrored, trimmed copy in
+// m.dashActivity rather than touching m.activity: the model Bubble Tea
+// drives is a separate copy (value-receiver Update/View), so m.activity is
+// only ever appended to inside Update, and
+// m.dashActivity - guarded by dashLock - is the dashboard's single-writer
+// equivalent. Mixing the two caused concurrent, unsynchronized appends to
+// the same backing array.
+func (m *model) logActivity(entry activityLog) {
+	m.activityChan <- entry
+
+	m.dashLock.Lock()
+	m.dashActivity = append(m.dashActivity, entry)
+	if len(m.dashActivity) > m.maxActivityLog {
+		m.dashActivity = m.dashActivity[len(m.dashActivity)-m.maxActivityLog:]
+	}
+	subscribers := make([]chan dashboardFrame, 0, len(m.dashSubscribers))
+	for ch := range m.dashSubscribers {
+		subscribers = append(subscribers, ch)
+	}
+	m.dashLock.Unlock()
+
+	if len(subscribers) == 0 {
+		return
+	}
+	frame := dashboardFrame{Type: "activity", Entry: ptr(toDashboardEntry(entry))}
+	for _, ch := range subscribers {
+		select {
+		case ch <- frame:
+		default:
+			// Slow subscriber; drop rather than block the transfer.
+		}
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// startDashboard is a tea.Cmd that starts the dashboard's HTTP server in
+// a goroutine, independent of the main file-serving listener.
+func (m *model) startDashboard() tea.Cmd {
+	return func() tea.Msg {
+		token, err := newDashboardToken()
+		if err != nil {
+			return serverErrMsg{fmt.Errorf("failed to start dashboard: %w", err)}
+		}
+		m.dashboardToken = token
+
+		listener, err := net.Listen("tcp", m.dashboardAddr)
+		if err != nil {
+			return serverErrMsg{fmt.Errorf("failed to start dashboard: %w", err)}
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", m.dashboardIndexHandler)
+		mux.HandleFunc("/ws", m.dashboardWSHandler)
+		mux.HandleFunc("/api/revoke", m.dashboardRevokeHandler)
+
+		server := &http.Server{Handler: mux}
+		m.dashboardServer = server
+		m.dashboardListener = listener
+
+		go func() {
+			<-m.shutdownChan
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(ctx)
+		}()
+		go server.Serve(listener)
+
+		return dashboardReadyMsg{url: fmt.Sprintf("http://%s/?token=%s", listener.Addr(), token)}
+	}
+}
+
+func (m *model) dashboardIndexHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if !m.dashboardAuthorized(r) {
+		http.Error(w, "missing or invalid token", http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	dashboardPage.Execute(w, struct{ Token string }{Token: m.dashboardToken})
+}
+
+func (m *model) dashboardWSHandler(w http.ResponseWriter, r *http.Request) {
+	if !m.dashboardAuthorized(r) {
+		http.Error(w, "missing or invalid token", http.StatusForbidden)
+		return
+	}
+	conn, err := dashboardUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	ch := make(chan dashboardFrame, 16)
+
+	m.dashLock.Lock()
+	if m.dashSubscribers == nil {
+		m.dashSubscribers = make(map[chan dashboardFrame]struct{})
+	}
+	m.dashSubscribers[ch] = struct{}{}
+	snapshot := make([]dashboardEntry, len(m.dashActivity))
+	for i, a := range m.dashActivity {
+		snapshot[i] = toDashboardEntry(a)
+	}
+	m.dashLock.Unlock()
+
+	defer func() {
+		m.dashLock.Lock()
+		delete(m.dashSubscribers, ch)
+		m.dashLock.Unlock()
+	}()
+
+	m.ipLock.Lock()
+	allowed := make([]string, 0, len(m.allowedFirstN))
+	for ip := range m.allowedFirstN {
+		allowed = append(allowed, ip)
+	}
+	downloadCount := m.downloadCount
+	m.ipLock.Unlock()
+
+	init := dashboardFrame{Type: "init", Activity: snapshot, DownloadCount: downloadCount, LimitN: m.limitN, AllowedIPs: allowed}
+	if conn.WriteJSON(init) != nil {
+		return
+	}
+
+	for msg := range ch {
+		if conn.WriteJSON(msg) != nil {
+			return
+		}
+	}
+}
+
+// dashboardRevokeHandler lets the dashboard kick an allowed IP out of the
+// allowedFirstN slot set, freeing the slot for another client.
+func (m *model) dashboardRevokeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !m.dashboardAuthorized(r) {
+		http.Error(w, "missing or invalid token", http.StatusForbidden)
+		return
+	}
+
+	var body struct {
+		IP string `json:"ip"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.IP == "" {
+		http.Error(w, "expected JSON body {\"ip\": \"...\"}", http.StatusBadRequest)
+		return
+	}
+
+	m.ipLock.Lock()
+	delete(m.allowedFirstN, body.IP)
+	m.ipLock.Unlock()
+
+	m.logActivity(activityLog{Timestamp: time.Now(), IP: body.IP, Action: "Revoked via dashboard", Style: styleIPRejected})
+	w.WriteHeader(http.StatusOK)
+}
+
+// dashboardPage is parsed once at startup; Token is the per-run
+// dashboardToken, injected so the page's own WS/fetch calls can authenticate.
+var dashboardPage = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>vrushie dashboard</title>
+<style>
+  body { font-family: -apple-system, sans-serif; background: #1e1e2e; color: #cdd6f4; max-width: 720px; margin: 2rem auto; padding: 0 1rem; }
+  h1 { color: #cba6f7; }
+  #stats { color: #a6e3a1; margin-bottom: 1rem; }
+  ul { list-style: none; padding: 0; }
+  li { padding: 0.25rem 0; border-bottom: 1px solid #313244; }
+  .ip { color: #89b4fa; }
+  button { background: #f38ba8; border: none; color: #1e1e2e; padding: 0.2rem 0.5rem; border-radius: 4px; cursor: pointer; }
+</style>
+</head>
+<body>
+<h1>🌸 vrushie dashboard 🌸</h1>
+<div id="stats">Connecting...</div>
+<h3>Allowed IPs</h3>
+<ul id="ips"></ul>
+<h3>Activity</h3>
+<ul id="activity"></ul>
+<script>
+const token = {{.Token}};
+const proto = location.protocol === "https:" ? "wss:" : "ws:";
+const ws = new WebSocket(proto + "//" + location.host + "/ws?token=" + encodeURIComponent(token));
+const stats = document.getElementById("stats");
+const ipsEl = document.getElementById("ips");
+const activityEl = document.getElementById("activity");
+
+function renderIPs(ips) {
+  ipsEl.innerHTML = "";
+  for (const ip of ips) {
+    const li = document.createElement("li");
+    li.innerHTML = '<span class="ip">' + ip + '</span> ';
+    const btn = document.createElement("button");
+    btn.textContent = "revoke";
+    btn.onclick = () => fetch("/api/revoke", {
+      method: "POST",
+      headers: { "X-Dashboard-Token": token },
+      body: JSON.stringify({ ip }),
+    });
+    li.appendChild(btn);
+    ipsEl.appendChild(li);
+  }
+}
+
+function prependEntry(e) {
+  const li = document.createElement("li");
+  li.textContent = e.timestamp + " [" + e.ip + "] " + e.action;
+  activityEl.prepend(li);
+}
+
+ws.onmessage = (ev) => {
+  const msg = JSON.parse(ev.data);
+  if (msg.type === "init") {
+    stats.textContent = "Downloads: " + msg.downloadCount + " / " + msg.limitN;
+    renderIPs(msg.allowedIPs || []);
+    for (const e of msg.activity || []) prependEntry(e);
+  } else if (msg.type === "activity") {
+    prependEntry(msg.entry);
+  }
+};
+</script>
+</body>
+</html>
+`))
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"flag"
 	"fmt"
@@ -16,6 +17,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -32,6 +34,17 @@ var (
 	showHelpLong    = flag.Bool("help", false, "Show help message")
 	showVersion     = flag.Bool("v", false, "Show version information")
 	showVersionLong = flag.Bool("version", false, "Show version information")
+	publicMode      = flag.Bool("public", false, "Share over a vrushie-relay server instead of listening directly (for NAT/no port-forwarding)")
+	relayAddr       = flag.String("relay", "", "host:port of the vrushie-relay server to dial when --public is set")
+	tlsEnabled      = flag.Bool("tls", false, "Serve over HTTPS (generates a self-signed cert unless --cert/--key are given)")
+	tlsAuto         = flag.Bool("tls-auto", false, "Serve over HTTPS with a freshly generated self-signed certificate")
+	tlsCertFile     = flag.String("cert", "", "TLS certificate file (PEM, chain-aware)")
+	tlsKeyFile      = flag.String("key", "", "TLS private key file (PEM)")
+	recvOutDir      = flag.String("out", ".", "Directory to save received files into (only used with `vrushie recv`)")
+	qrMode          = flag.String("qr", "on", "Show a terminal QR code for the primary shareable URL (\"off\" to disable)")
+	slugFlag        = flag.String("slug", "", "Custom short slug for the shareable URL (default: a random three-word slug)")
+	dashboardFlag   = flag.Bool("dashboard", false, "Expose a live web dashboard (activity log + controls) over WebSocket")
+	dashboardAddr   = flag.String("dashboard-addr", "127.0.0.1:0", "Address for the dashboard to listen on (loopback-only by default)")
 )
 
 // --- TUI Styles ---
@@ -53,29 +66,57 @@ var (
 // --- TUI Model ---
 
 type model struct {
-	spinner        spinner.Model
-	server         *http.Server
-	listener       net.Listener
-	shutdownChan   chan struct{}    // Channel to signal graceful shutdown
-	errChan        chan error       // Channel for server errors
-	activityChan   chan activityLog // Channel for logging access attempts/downloads
-	serverReady    bool
-	servingURL     string
-	filePath       string
-	fileName       string
-	fileSize       int64
-	accessMode     string
-	limitN         int                 // 0 means serve-once logic, >0 means N downloads/IPs
-	specificIPs    map[string]struct{} // Set of specifically allowed IPs
-	allowedFirstN  map[string]struct{} // Set of the first N IPs that connected (if limitN > 0 and specificIPs is empty)
-	ipLock         sync.Mutex          // Protects access maps and download count
-	downloadCount  int
-	activity       []activityLog // Log of recent activities
-	maxActivityLog int           // Max number of log entries to keep
-	lastError      error
-	quitting       bool
-	width          int
-	height         int
+	spinner           spinner.Model
+	server            *http.Server
+	listener          net.Listener
+	shutdownChan      chan struct{}    // Channel to signal graceful shutdown
+	errChan           chan error       // Channel for server errors
+	activityChan      chan activityLog // Channel for logging access attempts/downloads
+	serverReady       bool
+	servingURL        string
+	public            bool   // true when sharing via a vrushie-relay instead of listening directly
+	relayAddr         string // host:port of the relay to dial when public is true
+	publicURL         string // https://relay/s/<token>/ shown alongside the LAN URLs
+	relay             *relaySession
+	useTLS            bool
+	certFile          string
+	keyFile           string
+	autoTLS           bool
+	certFingerprint   string // SHA-256 fingerprint of the serving cert, shown so recipients can verify self-signed certs
+	recvMode          bool   // true when running as `vrushie recv`, accepting uploads instead of serving a file
+	outDir            string // destination directory for received files (recv mode)
+	qrEnabled         bool
+	slug              string // short wordlist slug the file is also reachable at, e.g. /plum-otter-dawn
+	slugURL           string // primary URL with the slug appended, shown in the TUI
+	qrArt             string // rendered terminal QR code for the primary shareable URL
+	filePath          string
+	fileName          string
+	fileSize          int64
+	accessMode        string
+	limitN            int                 // 0 means serve-once logic, >0 means N downloads/IPs
+	specificIPs       map[string]struct{} // Set of specifically allowed IPs
+	allowedFirstN     map[string]struct{} // Set of the first N IPs that connected (if limitN > 0 and specificIPs is empty)
+	ipLock            sync.Mutex          // Protects access maps and download count
+	downloadCount     int
+	activity          []activityLog                    // Log of recent activities
+	maxActivityLog    int                              // Max number of log entries to keep
+	progressChan      chan progressMsg                 // Channel for live per-transfer byte counts
+	transfers         map[string]*transferState        // Live transfers keyed by ip+connID
+	transferOrder     []string                         // Display order for transfers
+	servedRanges      map[string][]byteRange           // Bytes served so far per IP, for Range-aware completion
+	dashboardOn       bool                             // true when --dashboard exposes the live web dashboard
+	dashboardAddr     string                           // address the dashboard HTTP server binds to
+	dashboardURL      string                           // actual bound address, shown in the TUI once ready
+	dashboardServer   *http.Server                     // dashboard's own HTTP server, independent of m.server
+	dashboardListener net.Listener                     // dashboard's listener, closed on shutdown via dashboardServer.Shutdown
+	dashboardToken    string                           // per-run token required by every dashboard request; see dashboardAuthorized
+	dashLock          sync.Mutex                       // Protects dashActivity and dashSubscribers below
+	dashActivity      []activityLog                    // Dashboard's own mirrored, trimmed activity log (single-writer via dashLock; never touches m.activity)
+	dashSubscribers   map[chan dashboardFrame]struct{} // One channel per open /ws connection
+	lastError         error
+	quitting          bool
+	width             int
+	height            int
 }
 
 type activityLog struct {
@@ -87,14 +128,23 @@ type activityLog struct {
 
 // --- TUI Messages ---
 
-type serverReadyMsg struct{ url string }
+type serverReadyMsg struct {
+	url         string
+	publicURL   string // empty unless --public is set
+	fingerprint string // SHA-256 fingerprint of the serving cert, empty unless --tls or --tls-auto is set
+	slugURL     string // primary URL with the short slug appended, e.g. .../plum-otter-dawn
+	qrArt       string // rendered terminal QR code for slugURL, empty if --qr=off or recv mode
+}
 type serverErrMsg struct{ err error }
 type activityMsg struct{ log activityLog }
 type shutdownMsg struct{} // Message to initiate shutdown
 
 // --- Bubbletea Implementation ---
 
-func initialModel(filePath string) model {
+// newBaseModel builds the parts of model shared by both send mode
+// (initialModel) and receive mode (initialRecvModel): channels, style
+// state, and the -n/--ips/--tls/--public access configuration.
+func newBaseModel() model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot // Or choose another cute one: Line, Jump, Pulse, Points, Globe, Moon, Monkey
 	s.Style = styleSpinner
@@ -104,9 +154,19 @@ func initialModel(filePath string) model {
 		shutdownChan:   make(chan struct{}),
 		errChan:        make(chan error, 1),        // Buffered to prevent blocking
 		activityChan:   make(chan activityLog, 10), // Buffered channel for activities
+		progressChan:   make(chan progressMsg, 32), // Buffered channel for transfer byte counts
+		transfers:      make(map[string]*transferState),
+		servedRanges:   make(map[string][]byteRange),
 		serverReady:    false,
-		filePath:       filePath,
-		fileName:       filepath.Base(filePath),
+		public:         *publicMode,
+		relayAddr:      *relayAddr,
+		useTLS:         *tlsEnabled || *tlsAuto,
+		certFile:       *tlsCertFile,
+		keyFile:        *tlsKeyFile,
+		autoTLS:        *tlsAuto || (*tlsEnabled && *tlsCertFile == "" && *tlsKeyFile == ""),
+		qrEnabled:      *qrMode != "off",
+		dashboardOn:    *dashboardFlag,
+		dashboardAddr:  *dashboardAddr,
 		limitN:         *limitN,
 		specificIPs:    make(map[string]struct{}),
 		allowedFirstN:  make(map[string]struct{}),
@@ -114,12 +174,6 @@ func initialModel(filePath string) model {
 		activity:       make([]activityLog, 0, 10),
 	}
 
-	// Determine File Size
-	info, err := os.Stat(filePath)
-	if err == nil {
-		m.fileSize = info.Size()
-	} // Error handled later in main
-
 	// Parse specific IPs if provided
 	if *allowedIPs != "" {
 		ips := strings.Split(*allowedIPs, ",")
@@ -139,11 +193,44 @@ func initialModel(filePath string) model {
 		m.limitN = 1
 	}
 
+	if *slugFlag != "" {
+		m.slug = *slugFlag
+	} else if s, err := randomSlug(); err == nil {
+		m.slug = s
+	}
+
+	return m
+}
+
+func initialModel(filePath string) model {
+	m := newBaseModel()
+	m.filePath = filePath
+	m.fileName = filepath.Base(filePath)
+
+	// Determine File Size
+	info, err := os.Stat(filePath)
+	if err == nil {
+		m.fileSize = info.Size()
+	} // Error handled later in main
+
+	return m
+}
+
+// initialRecvModel builds the model for `vrushie recv`: it accepts
+// uploads into outDir instead of serving a file.
+func initialRecvModel(outDir string) model {
+	m := newBaseModel()
+	m.recvMode = true
+	m.outDir = outDir
 	return m
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, m.startServer())
+	cmds := []tea.Cmd{m.spinner.Tick, m.startServer()}
+	if m.dashboardOn {
+		cmds = append(cmds, m.startDashboard())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -173,6 +260,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case serverReadyMsg:
 		m.serverReady = true
 		m.servingURL = msg.url
+		m.publicURL = msg.publicURL
+		m.certFingerprint = msg.fingerprint
+		m.slugURL = msg.slugURL
+		m.qrArt = msg.qrArt
 		return m, nil
 
 	case serverErrMsg:
@@ -180,6 +271,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.quitting = true // Assume fatal error
 		return m, tea.Quit
 
+	case dashboardReadyMsg:
+		m.dashboardURL = msg.url
+		return m, nil
+
 	case activityMsg:
 		m.activity = append(m.activity, msg.log)
 		// Keep the log trimmed
@@ -187,8 +282,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.activity = m.activity[len(m.activity)-m.maxActivityLog:]
 		}
 
-		// Check if a successful download triggers shutdown
-		if msg.log.Action == "Download Complete" {
+		// Check if a successful transfer (download or, in recv mode, upload) triggers shutdown
+		if msg.log.Action == "Download Complete" || strings.HasPrefix(msg.log.Action, "Upload Complete") {
 			m.ipLock.Lock()
 			m.downloadCount++
 			shouldShutdown := (m.limitN == 1 || (m.limitN > 1 && m.downloadCount >= m.limitN))
@@ -201,6 +296,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case progressMsg:
+		return m, m.applyProgress(msg)
+
+	case progress.FrameMsg:
+		var cmds []tea.Cmd
+		for _, ts := range m.transfers {
+			updated, cmd := ts.bar.Update(msg)
+			if pm, ok := updated.(progress.Model); ok {
+				ts.bar = pm
+			}
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+		return m, tea.Batch(cmds...)
+
 	case shutdownMsg: // Received internally when download limit reached
 		m.quitting = true
 		m.activity = append(m.activity, activityLog{
@@ -237,8 +348,12 @@ func (m model) View() string {
 	s.WriteString("\n\n")
 
 	// File Info
-	s.WriteString(fmt.Sprintf("Serving File: %s\n", styleSecondary.Render(m.fileName)))
-	s.WriteString(fmt.Sprintf("Size: %s\n", styleSubtle.Render(formatBytes(m.fileSize))))
+	if m.recvMode {
+		s.WriteString(fmt.Sprintf("Receiving uploads into: %s\n", styleSecondary.Render(m.outDir)))
+	} else {
+		s.WriteString(fmt.Sprintf("Serving File: %s\n", styleSecondary.Render(m.fileName)))
+		s.WriteString(fmt.Sprintf("Size: %s\n", styleSubtle.Render(formatBytes(m.fileSize))))
+	}
 	s.WriteString("\n")
 
 	// Server Status
@@ -253,6 +368,26 @@ func (m model) View() string {
 				s.WriteString(fmt.Sprintf("  %s\n", styleURL.Render(url)))
 			}
 		}
+		if m.publicURL != "" {
+			s.WriteString(fmt.Sprintf("  %s %s\n", styleSecondary.Render("(public)"), styleURL.Render(m.publicURL)))
+		}
+		if m.certFingerprint != "" {
+			s.WriteString(fmt.Sprintf("  %s %s\n", styleSubtle.Render("Cert SHA-256:"), styleSubtle.Render(m.certFingerprint)))
+		}
+		if m.recvMode {
+			firstURL := strings.SplitN(m.servingURL, "\n", 2)[0]
+			s.WriteString(fmt.Sprintf("\n%s\n", styleSubtle.Render(fmt.Sprintf("  curl -F 'file=@thing' %s", firstURL))))
+		}
+		if m.slugURL != "" {
+			s.WriteString(fmt.Sprintf("  %s %s\n", styleSubtle.Render("Short link:"), styleURL.Render(m.slugURL)))
+		}
+		if m.qrArt != "" {
+			s.WriteString("\n")
+			s.WriteString(m.qrArt)
+		}
+		if m.dashboardURL != "" {
+			s.WriteString(fmt.Sprintf("  %s %s\n", styleSubtle.Render("Dashboard:"), styleURL.Render(m.dashboardURL)))
+		}
 	}
 	s.WriteString("\n")
 
@@ -296,6 +431,22 @@ func (m model) View() string {
 		}
 	}
 
+	// Active Transfers
+	if len(m.transferOrder) > 0 {
+		s.WriteString("\nActive Transfers:\n")
+		for _, key := range m.transferOrder {
+			t, ok := m.transfers[key]
+			if !ok {
+				continue
+			}
+			status := fmt.Sprintf("%s / %s", formatBytes(t.bytesWritten), formatBytes(t.totalBytes))
+			if t.done {
+				status = "Complete"
+			}
+			s.WriteString(fmt.Sprintf("  %s %s %s\n", styleSubtle.Render(t.ip), t.bar.View(), styleSubtle.Render(status)))
+		}
+	}
+
 	// Footer/Instructions
 	if !m.quitting {
 		s.WriteString(styleInstructions.Render("\nPress 'q' or Ctrl+C to shut down manually."))
@@ -361,9 +512,23 @@ func getOutboundIPs() []string {
 
 // --- Server Logic ---
 
-// startServer is a tea.Cmd that starts the HTTP server in a goroutine
+// startServer is a tea.Cmd that starts the HTTP server in a goroutine. It
+// always binds the direct LAN listener; when --public is also set, it
+// additionally dials the relay and serves there too, so the relay URL is
+// shown alongside the LAN URLs rather than replacing them.
 func (m *model) startServer() tea.Cmd {
 	return func() tea.Msg {
+		mux := http.NewServeMux()
+		if m.recvMode {
+			mux.HandleFunc("/", m.recvHandler)
+		} else {
+			mux.HandleFunc("/", m.fileHandler) // Pass model method
+		}
+		m.server = &http.Server{
+			Handler: mux,
+			// Add timeouts for robustness? e.g., ReadTimeout, WriteTimeout
+		}
+
 		// Create listener
 		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
 		if err != nil {
@@ -373,56 +538,157 @@ func (m *model) startServer() tea.Cmd {
 
 		// Get actual port if random was requested
 		actualPort := listener.Addr().(*net.TCPAddr).Port
+		ips := getOutboundIPs()
+
+		scheme := "http"
+		if m.useTLS {
+			tlsConfig, err := m.buildTLSConfig(ips)
+			if err != nil {
+				return serverErrMsg{err}
+			}
+			listener = tls.NewListener(listener, tlsConfig)
+			m.listener = listener
+			scheme = "https"
+		}
 
 		// Determine server URLs
-		ips := getOutboundIPs()
 		var urlBuilder strings.Builder
 		for _, ip := range ips {
-			urlBuilder.WriteString(fmt.Sprintf("http://%s:%d/\n", ip, actualPort))
+			urlBuilder.WriteString(fmt.Sprintf("%s://%s:%d/\n", scheme, ip, actualPort))
 		}
 		// Always include localhost
 		if !contains(ips, "127.0.0.1") {
-			urlBuilder.WriteString(fmt.Sprintf("http://127.0.0.1:%d/\n", actualPort))
+			urlBuilder.WriteString(fmt.Sprintf("%s://127.0.0.1:%d/\n", scheme, actualPort))
 		}
 		serverURL := strings.TrimSpace(urlBuilder.String())
 
-		// Create server
-		mux := http.NewServeMux()
-		mux.HandleFunc("/", m.fileHandler) // Pass model method
-		m.server = &http.Server{
-			Handler: mux,
-			// Add timeouts for robustness? e.g., ReadTimeout, WriteTimeout
-		}
+		m.watchShutdown()
+		m.serveOn(listener)
 
-		// Start server in a goroutine
-		go func() {
-			<-m.shutdownChan // Wait for shutdown signal
-			log.Println("Shutdown signal received, stopping server...")
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Graceful shutdown timeout
-			defer cancel()
-			if err := m.server.Shutdown(ctx); err != nil {
-				// Send error back to main loop if shutdown fails
-				m.errChan <- fmt.Errorf("server shutdown failed: %w", err)
-			} else {
-				log.Println("Server stopped gracefully.")
-			}
-			close(m.errChan) // Signal that shutdown goroutine is done
-		}()
-
-		// Start listening in another goroutine, send errors back via channel
-		go func() {
-			log.Printf("Server starting on port %d...", actualPort)
-			if err := m.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
-				m.errChan <- fmt.Errorf("server failed: %w", err)
+		var publicURL string
+		if m.public {
+			publicURL, err = m.startPublicServer()
+			if err != nil {
+				return serverErrMsg{err}
 			}
-			log.Println("Server Serve() function finished.")
-		}()
+		}
+
+		primary := serverURL
+		if idx := strings.Index(serverURL, "\n"); idx != -1 {
+			primary = serverURL[:idx]
+		}
+		slugURL, qrArt := m.shareExtras(primary)
 
 		// Report server ready via message
-		return serverReadyMsg{url: serverURL}
+		return serverReadyMsg{url: serverURL, publicURL: publicURL, fingerprint: m.certFingerprint, slugURL: slugURL, qrArt: qrArt}
 	}
 }
 
+// shareExtras computes the slug-appended URL and (if enabled) a terminal
+// QR code for primaryURL. Recv mode has no download slug, so both are
+// skipped there.
+func (m *model) shareExtras(primaryURL string) (slugURL, qrArt string) {
+	if m.recvMode {
+		return "", ""
+	}
+	slugURL = withSlug(primaryURL, m.slug)
+	if m.qrEnabled {
+		if art, err := renderTerminalQR(slugURL); err == nil {
+			qrArt = art
+		}
+	}
+	return slugURL, qrArt
+}
+
+// buildTLSConfig produces the tls.Config used to wrap the listener: either
+// a freshly generated self-signed certificate covering hosts, or the
+// explicit --cert/--key pair the user provided. It also records the leaf
+// certificate's fingerprint on the model for display in the TUI.
+func (m *model) buildTLSConfig(hosts []string) (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+
+	if m.autoTLS {
+		cert, err = generateSelfSignedCert(hosts)
+	} else {
+		cert, err = loadCertChain(m.certFile, m.keyFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("TLS setup failed: %w", err)
+	}
+
+	if cert.Leaf != nil {
+		m.certFingerprint = certFingerprint(cert.Leaf)
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// startPublicServer dials the configured relay, opens a yamux session on
+// the connection, and serves the same mux to every stream the relay hands
+// back, in addition to (not instead of) the direct LAN listener startServer
+// already bound - --public adds a relay URL alongside the LAN ones.
+func (m *model) startPublicServer() (string, error) {
+	if m.relayAddr == "" {
+		return "", fmt.Errorf("--public requires --relay <host:port>")
+	}
+
+	rs, err := dialRelay(m.relayAddr)
+	if err != nil {
+		return "", err
+	}
+	m.relay = rs
+
+	m.serveOn(&yamuxStreamListener{session: rs.session})
+
+	return publicURL(m.relayAddr, rs.token), nil
+}
+
+// watchShutdown starts the single goroutine that, on shutdownChan being
+// closed, shuts down m.server - shared by every listener serveOn is given,
+// so one Shutdown call stops all of them - and closes the relay session if
+// --public opened one. Call this once per run regardless of how many
+// listeners serveOn is given.
+func (m *model) watchShutdown() {
+	go func() {
+		<-m.shutdownChan // Wait for shutdown signal
+		log.Println("Shutdown signal received, stopping server...")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Graceful shutdown timeout
+		defer cancel()
+		if err := m.server.Shutdown(ctx); err != nil {
+			// Send error back to main loop if shutdown fails
+			select {
+			case m.errChan <- fmt.Errorf("server shutdown failed: %w", err):
+			default:
+			}
+		} else {
+			log.Println("Server stopped gracefully.")
+		}
+		if m.relay != nil {
+			m.relay.Close()
+		}
+		close(m.errChan) // Signal that shutdown goroutine is done
+	}()
+}
+
+// serveOn runs m.server.Serve on listener in its own goroutine, reporting
+// any non-shutdown error back via m.errChan. Safe to call more than once -
+// e.g. once for the direct LAN listener and once for the relay's yamux
+// stream listener when --public is set - since http.Server.Serve supports
+// being driven by multiple listeners concurrently.
+func (m *model) serveOn(listener net.Listener) {
+	go func() {
+		log.Println("Server starting...")
+		if err := m.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			select {
+			case m.errChan <- fmt.Errorf("server failed: %w", err):
+			default:
+			}
+		}
+		log.Println("Server Serve() function finished.")
+	}()
+}
+
 // waitForShutdown waits for the server goroutine to finish shutting down
 func (m *model) waitForShutdown() tea.Cmd {
 	return func() tea.Msg {
@@ -436,14 +702,33 @@ func (m *model) waitForShutdown() tea.Cmd {
 }
 
 // fileHandler is the HTTP handler function
-func (m *model) fileHandler(w http.ResponseWriter, r *http.Request) {
-	// Get client IP (handle potential proxies later if needed)
+// resolveClientIP extracts the real client IP for a request. In --public
+// mode the connection arrives from the relay over a yamux stream, so
+// r.RemoteAddr is the relay's session address, not the real client; trust
+// the X-Forwarded-For header it injects instead. The relay sets that header
+// and httputil.ReverseProxy then appends the same address ("a, a"), so only
+// the first entry is used.
+func (m *model) resolveClientIP(r *http.Request) string {
+	if m.public {
+		xff := r.Header.Get("X-Forwarded-For")
+		if ip := strings.TrimSpace(strings.Split(xff, ",")[0]); ip != "" {
+			return ip
+		}
+	}
 	ip, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
-		ip = r.RemoteAddr // Fallback if split fails
+		return r.RemoteAddr // Fallback if split fails
 	}
+	return ip
+}
 
+// checkAccess applies the same -n / --ips / access-mode gating to every
+// handler (download or upload): specific allowed IPs, first-N-unique-IPs,
+// or serve/receive-once, plus the overall transfer-count limit.
+func (m *model) checkAccess(ip string) (bool, string) {
 	m.ipLock.Lock()
+	defer m.ipLock.Unlock()
+
 	isAllowed := false
 	reason := "Access denied"
 
@@ -471,37 +756,50 @@ func (m *model) fileHandler(w http.ResponseWriter, r *http.Request) {
 		isAllowed = true
 	}
 
-	// Check if download limit is already reached (even if IP is allowed)
-	// This handles the N > 1 case where an allowed IP tries after N downloads finished
+	// Check if the transfer limit is already reached (even if IP is allowed)
+	// This handles the N > 1 case where an allowed IP tries after N transfers finished
 	if isAllowed && m.limitN > 1 && m.downloadCount >= m.limitN {
 		isAllowed = false
-		reason = fmt.Sprintf("Download limit of %d already reached", m.limitN)
+		reason = fmt.Sprintf("Transfer limit of %d already reached", m.limitN)
 	}
-	// This handles the serve-once case after the first download finished
+	// This handles the serve/receive-once case after the first transfer finished
 	if isAllowed && m.limitN == 1 && m.downloadCount > 0 {
 		isAllowed = false
-		reason = "File has already been downloaded"
+		reason = "File has already been transferred"
+	}
+
+	return isAllowed, reason
+}
+
+func (m *model) fileHandler(w http.ResponseWriter, r *http.Request) {
+	// The file is reachable at "/" and at its short slug only; anything
+	// else (typos, scanners) gets a plain 404 rather than leaking the file.
+	if r.URL.Path != "/" && r.URL.Path != "/"+m.slug {
+		http.NotFound(w, r)
+		return
 	}
 
-	m.ipLock.Unlock() // Release lock before logging and serving
+	ip := m.resolveClientIP(r)
+
+	isAllowed, reason := m.checkAccess(ip)
 
 	// Log activity and potentially reject
 	if !isAllowed {
 		logMsg := activityLog{Timestamp: time.Now(), IP: ip, Action: fmt.Sprintf("Rejected: %s", reason), Style: styleIPRejected}
-		m.activityChan <- logMsg // Send to TUI via channel
+		m.logActivity(logMsg) // Send to TUI (and dashboard) via channel
 		http.Error(w, reason, http.StatusForbidden)
 		return
 	}
 
 	// Log allowed connection attempt
 	logMsg := activityLog{Timestamp: time.Now(), IP: ip, Action: "Connected & Allowed", Style: styleIPAllowed}
-	m.activityChan <- logMsg
+	m.logActivity(logMsg)
 
 	// --- Serve the file ---
 	file, err := os.Open(m.filePath)
 	if err != nil {
 		errMsg := activityLog{Timestamp: time.Now(), IP: ip, Action: fmt.Sprintf("Error opening file: %s", err), Style: styleError}
-		m.activityChan <- errMsg
+		m.logActivity(errMsg)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
@@ -510,27 +808,79 @@ func (m *model) fileHandler(w http.ResponseWriter, r *http.Request) {
 	// Set headers for download
 	w.Header().Set("Content-Disposition", "attachment; filename="+strconv.Quote(m.fileName))
 	w.Header().Set("Content-Type", "application/octet-stream") // Generic byte stream
-	w.Header().Set("Content-Length", strconv.FormatInt(m.fileSize, 10))
 
-	// Use ServeContent for efficiency (handles Range requests etc.)
-	// http.ServeContent(w, r, m.fileName, time.Time{}, file) // Simpler version
+	modTime := time.Time{}
+	if info, statErr := file.Stat(); statErr == nil {
+		modTime = info.ModTime()
+	}
+
+	// http.ServeContent (rather than a raw io.Copy) gives us Range requests,
+	// conditional GETs, and resumable downloads for free - browsers and
+	// `curl -C -` both rely on this. Wrap the writer so we can report live
+	// progress without interfering with ServeContent's own logic.
+	cw := &countingResponseWriter{
+		ResponseWriter: w,
+		key:            ip + "+" + nextConnID(),
+		ip:             ip,
+		totalBytes:     m.fileSize,
+		startTime:      time.Now(),
+		progressChan:   m.progressChan,
+	}
+	http.ServeContent(cw, r, m.fileName, modTime, file)
+
+	served, trackable := servedByteRange(r, w.Header(), cw.written, m.fileSize)
 
-	// Or io.Copy for explicit control/error checking (though ServeContent is usually better)
-	_, copyErr := io.Copy(w, file)
+	var complete bool
+	if trackable {
+		m.ipLock.Lock()
+		m.servedRanges[ip] = mergeByteRange(m.servedRanges[ip], served)
+		complete = rangeCoversAll(m.servedRanges[ip], m.fileSize)
+		m.ipLock.Unlock()
+	}
 
-	// Check if the copy was successful *from the server's perspective*
-	// This doesn't perfectly guarantee the client got everything, but it's the best we can easily do.
-	if copyErr == nil {
-		// Log successful download completion
+	m.progressChan <- progressMsg{
+		key:          cw.key,
+		ip:           ip,
+		bytesWritten: cw.written,
+		totalBytes:   m.fileSize,
+		startTime:    cw.startTime,
+		done:         true,
+	}
+
+	if complete {
+		// Log successful download completion, only once the full byte range
+		// has actually been served - a resumed download sends many partial
+		// requests and must not exhaust the -n limit on the first one.
 		successMsg := activityLog{Timestamp: time.Now(), IP: ip, Action: "Download Complete", Style: styleSuccess}
 		// Send via channel - this will trigger the Update logic to check shutdown condition
-		m.activityChan <- successMsg
+		m.logActivity(successMsg)
 	} else {
-		// Log potential error during transfer
-		errMsg := activityLog{Timestamp: time.Now(), IP: ip, Action: fmt.Sprintf("Error during transfer: %s", copyErr), Style: styleError}
-		m.activityChan <- errMsg
-		// Don't explicitly trigger shutdown on transfer error
+		partialMsg := activityLog{Timestamp: time.Now(), IP: ip, Action: fmt.Sprintf("Served %s", formatBytes(cw.written)), Style: styleSubtle}
+		m.logActivity(partialMsg)
+	}
+}
+
+// servedByteRange figures out which inclusive byte span http.ServeContent
+// just sent, using the Content-Range header it sets for partial responses
+// and falling back to the full file for a plain (non-Range) request. The
+// second return value is false for a multi-range request (Content-Type:
+// multipart/byteranges): ServeContent sets no top-level Content-Range there,
+// and `written` counts multipart boundaries/headers rather than file bytes,
+// so the span can't be trusted for completion tracking.
+func servedByteRange(r *http.Request, headers http.Header, written, fileSize int64) (byteRange, bool) {
+	if strings.HasPrefix(headers.Get("Content-Type"), "multipart/byteranges") {
+		return byteRange{}, false
+	}
+
+	if r.Header.Get("Range") == "" || headers.Get("Content-Range") == "" {
+		return byteRange{start: 0, end: written - 1}, true
+	}
+
+	var start, end, total int64
+	if _, err := fmt.Sscanf(headers.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total); err == nil {
+		return byteRange{start: start, end: end}, true
 	}
+	return byteRange{start: 0, end: written - 1}, true
 }
 
 // contains checks if a string slice contains a specific string.
@@ -559,6 +909,11 @@ Examples:
   vrushie -n 3 photo.jpg                  # Serve to first 3 unique IPs
   vrushie -port 8080 video.mp4           # Serve on specific port
   vrushie -ips "192.168.1.10,192.168.1.20" file.zip  # Only allow specific IPs
+  vrushie -public -relay relay.example:9090 file.zip # Share without port-forwarding
+  vrushie -tls-auto secret.pdf                        # Serve over HTTPS with a generated cert
+  vrushie recv --out ./incoming                       # Accept one upload into ./incoming
+  vrushie --slug pickup photo.jpg                     # Reachable at /pickup, with a terminal QR code
+  vrushie --dashboard large-archive.zip                # Also expose a live web dashboard on loopback
 
 Options:
 `, version)
@@ -585,9 +940,20 @@ func getFilePath() (string, error) {
 // --- Main Function ---
 
 func main() {
+	// `vrushie recv [options]` flips the tool into upload-receiving mode.
+	// Strip the "recv" token before flag.Parse so the flags that follow it
+	// (--out, --port, -n, --ips, ...) parse as usual. "recv" is only
+	// recognised as the first argument.
+	args := os.Args[1:]
+	recvMode := false
+	if len(args) > 0 && args[0] == "recv" {
+		recvMode = true
+		args = args[1:]
+	}
+
 	// Custom usage function
 	flag.Usage = printUsage
-	flag.Parse()
+	flag.CommandLine.Parse(args)
 
 	// Handle help and version flags
 	if *showHelp || *showHelpLong {
@@ -600,42 +966,57 @@ func main() {
 		os.Exit(0)
 	}
 
-	// --- Get file path ---
-	filePath, err := getFilePath()
-	if err != nil {
-		fmt.Println(styleError.Render("❌ Error: No file specified"))
-		fmt.Println(styleSubtle.Render("\nUsage: vrushie [options] <file>"))
-		fmt.Println(styleSubtle.Render("Try 'vrushie --help' for more information."))
-		os.Exit(1)
-	}
-
-	// --- Input Validation ---
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		fmt.Println(styleError.Render(fmt.Sprintf("❌ Error: File not found: %s", filePath)))
-		os.Exit(1)
-	}
 	if *limitN < 1 && *allowedIPs == "" {
 		// If -n is 0 or less, and no specific IPs are given, default to serve-once.
 		fmt.Println(styleSubtle.Render("⚠️  Warning: -n must be 1 or greater. Defaulting to serve-once (n=1)."))
 		*limitN = 1
 	}
 
+	var m model
+	if recvMode {
+		if info, err := os.Stat(*recvOutDir); err != nil || !info.IsDir() {
+			fmt.Println(styleError.Render(fmt.Sprintf("❌ Error: --out directory not found: %s", *recvOutDir)))
+			os.Exit(1)
+		}
+		m = initialRecvModel(*recvOutDir)
+	} else {
+		// --- Get file path ---
+		filePath, err := getFilePath()
+		if err != nil {
+			fmt.Println(styleError.Render("❌ Error: No file specified"))
+			fmt.Println(styleSubtle.Render("\nUsage: vrushie [options] <file>"))
+			fmt.Println(styleSubtle.Render("Try 'vrushie --help' for more information."))
+			os.Exit(1)
+		}
+
+		// --- Input Validation ---
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			fmt.Println(styleError.Render(fmt.Sprintf("❌ Error: File not found: %s", filePath)))
+			os.Exit(1)
+		}
+		m = initialModel(filePath)
+	}
+
 	// Setup logging (optional, for debugging server internals)
 	// You can pipe this to a file if needed: go run main.go ... >> server.log 2>&1
 	log.SetOutput(io.Discard) // Disable standard logger by default, TUI shows info
 	// log.SetOutput(os.Stderr) // Enable if debugging needed
 
 	// Create and run the Bubble Tea program
-	model := initialModel(filePath)
-	p := tea.NewProgram(model, tea.WithAltScreen()) // Use AltScreen for clean exit
+	p := tea.NewProgram(m, tea.WithAltScreen()) // Use AltScreen for clean exit
 
 	// Run Bubble Tea. This blocks until Quit is received.
 	// Need to use p.Send for channel communication *after* Run starts
 	go func() {
-		for activity := range model.activityChan {
+		for activity := range m.activityChan {
 			p.Send(activityMsg{log: activity})
 		}
 	}()
+	go func() {
+		for progress := range m.progressChan {
+			p.Send(progress)
+		}
+	}()
 
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("❌ Oh no! There was an error: %v\n", err)
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// connCounter hands out a unique id per inbound request so concurrent
+// partial (Range) transfers from the same IP get their own progress bar.
+var connCounter int64
+
+func nextConnID() string {
+	return fmt.Sprintf("%d", atomic.AddInt64(&connCounter, 1))
+}
+
+// transferState is one row of live progress shown under the Activity Log.
+type transferState struct {
+	ip           string
+	bytesWritten int64
+	totalBytes   int64
+	startTime    time.Time
+	bar          progress.Model
+	done         bool
+}
+
+// progressMsg reports the latest byte count for one transfer; the model
+// uses it to create/update the matching transferState.
+type progressMsg struct {
+	key          string // ip+connID
+	ip           string
+	bytesWritten int64
+	totalBytes   int64
+	startTime    time.Time
+	done         bool
+}
+
+// countingResponseWriter wraps the ResponseWriter handed to http.ServeContent
+// so every Write can be reported to the TUI without touching ServeContent's
+// Range/conditional-GET handling.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	key          string
+	ip           string
+	totalBytes   int64
+	written      int64
+	startTime    time.Time
+	progressChan chan<- progressMsg
+}
+
+func (c *countingResponseWriter) Write(p []byte) (int, error) {
+	n, err := c.ResponseWriter.Write(p)
+	c.written += int64(n)
+	select {
+	case c.progressChan <- progressMsg{
+		key:          c.key,
+		ip:           c.ip,
+		bytesWritten: c.written,
+		totalBytes:   c.totalBytes,
+		startTime:    c.startTime,
+	}:
+	default:
+		// Drop the update rather than block the transfer if the TUI is behind.
+	}
+	return n, err
+}
+
+// countingWriter is the recv-mode equivalent of countingResponseWriter: it
+// reports every Write to an arbitrary io.Writer (an *os.File on disk)
+// instead of an HTTP response, so upload progress reuses the same bars.
+type countingWriter struct {
+	w            io.Writer
+	key          string
+	ip           string
+	totalBytes   int64
+	written      int64
+	startTime    time.Time
+	progressChan chan<- progressMsg
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.written += int64(n)
+	select {
+	case c.progressChan <- progressMsg{
+		key:          c.key,
+		ip:           c.ip,
+		bytesWritten: c.written,
+		totalBytes:   c.totalBytes,
+		startTime:    c.startTime,
+	}:
+	default:
+	}
+	return n, err
+}
+
+// applyProgress updates (or creates) the transferState for msg.key and
+// returns the tea.Cmd that keeps its progress.Model animating.
+func (m *model) applyProgress(msg progressMsg) tea.Cmd {
+	if m.transfers == nil {
+		m.transfers = make(map[string]*transferState)
+	}
+
+	ts, ok := m.transfers[msg.key]
+	if !ok {
+		ts = &transferState{
+			ip:         msg.ip,
+			totalBytes: msg.totalBytes,
+			startTime:  msg.startTime,
+			bar:        progress.New(progress.WithDefaultGradient()),
+		}
+		m.transfers[msg.key] = ts
+		m.transferOrder = append(m.transferOrder, msg.key)
+		if max := m.maxActivityLog; max > 0 && len(m.transferOrder) > max {
+			stale := m.transferOrder[0]
+			m.transferOrder = m.transferOrder[1:]
+			delete(m.transfers, stale)
+		}
+	}
+	ts.bytesWritten = msg.bytesWritten
+	ts.done = msg.done
+
+	var percent float64
+	if ts.totalBytes > 0 {
+		percent = float64(ts.bytesWritten) / float64(ts.totalBytes)
+	}
+	return ts.bar.SetPercent(percent)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// qrQuietZone is the number of blank module rows/columns padded around the
+// code, matching the quiet zone most QR readers expect before scanning.
+const qrQuietZone = 2
+
+// slugWords is a small, easy-to-read wordlist used to build memorable
+// three-word slugs like "plum-otter-dawn" that are quick to type on a phone.
+var slugWords = []string{
+	"plum", "otter", "dawn", "cedar", "ember", "finch", "gale", "harbor",
+	"ivy", "juniper", "koala", "lotus", "maple", "nimbus", "opal", "quill",
+	"raven", "sable", "tundra", "umber", "violet", "willow", "yarrow", "zephyr",
+	"amber", "birch", "coral", "delta", "echo", "fable",
+}
+
+// randomSlug picks three words from slugWords (with crypto/rand so it
+// doesn't depend on process-start entropy) and joins them with dashes.
+func randomSlug() (string, error) {
+	parts := make([]string, 3)
+	for i := range parts {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(slugWords))))
+		if err != nil {
+			return "", err
+		}
+		parts[i] = slugWords[n.Int64()]
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// renderTerminalQR draws a QR code for content using Unicode half-block
+// characters (two matrix rows per printed row), so a typical URL fits in
+// roughly 25 terminal rows instead of 50.
+func renderTerminalQR(content string) (string, error) {
+	qr, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return "", err
+	}
+	bitmap := qr.Bitmap()
+
+	size := len(bitmap)
+	padded := make([][]bool, size+qrQuietZone*2)
+	for i := range padded {
+		padded[i] = make([]bool, size+qrQuietZone*2)
+	}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			padded[y+qrQuietZone][x+qrQuietZone] = bitmap[y][x]
+		}
+	}
+
+	var b strings.Builder
+	full := len(padded)
+	for y := 0; y < full; y += 2 {
+		for x := 0; x < full; x++ {
+			top := padded[y][x]
+			bottom := y+1 < full && padded[y+1][x]
+			switch {
+			case top && bottom:
+				b.WriteRune('█')
+			case top && !bottom:
+				b.WriteRune('▀')
+			case !top && bottom:
+				b.WriteRune('▄')
+			default:
+				b.WriteRune(' ')
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// withSlug appends /<slug> to a base URL like "http://1.2.3.4:5000/",
+// leaving it untouched when no slug is set.
+func withSlug(baseURL, slug string) string {
+	if slug == "" {
+		return baseURL
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + slug
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithSlug(t *testing.T) {
+	cases := []struct {
+		name    string
+		baseURL string
+		slug    string
+		want    string
+	}{
+		{"no slug leaves URL untouched", "http://1.2.3.4:5000/", "", "http://1.2.3.4:5000/"},
+		{"slug appended to trailing slash", "http://1.2.3.4:5000/", "plum-otter-dawn", "http://1.2.3.4:5000/plum-otter-dawn"},
+		{"slug appended without trailing slash", "http://1.2.3.4:5000", "plum-otter-dawn", "http://1.2.3.4:5000/plum-otter-dawn"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := withSlug(c.baseURL, c.slug); got != c.want {
+				t.Errorf("withSlug(%q, %q) = %q, want %q", c.baseURL, c.slug, got, c.want)
+			}
+		})
+	}
+}
+
+func TestRandomSlug(t *testing.T) {
+	slug, err := randomSlug()
+	if err != nil {
+		t.Fatalf("randomSlug() returned error: %v", err)
+	}
+	parts := strings.Split(slug, "-")
+	if len(parts) != 3 {
+		t.Fatalf("randomSlug() = %q, want three dash-separated words", slug)
+	}
+	for _, p := range parts {
+		if p == "" {
+			t.Fatalf("randomSlug() = %q, contains an empty word", slug)
+		}
+	}
+}
+
+func TestRandomSlugIsVaried(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		slug, err := randomSlug()
+		if err != nil {
+			t.Fatalf("randomSlug() returned error: %v", err)
+		}
+		seen[slug] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("randomSlug() produced the same value across %d calls, want variety", 20)
+	}
+}
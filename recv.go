@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// --- Reverse mode: `vrushie recv` accepts an upload from a peer ---
+//
+// This flips the tool around: instead of serving m.filePath to downloaders,
+// recvHandler streams an incoming upload to disk under m.outDir. It reuses
+// the exact same -n / --ips / access-mode gating and progress infrastructure
+// as the download path, so vrushie is a symmetric peer-to-peer file mover.
+
+// recvHandler handles POST/PUT / by streaming the request body to disk.
+// It accepts either multipart/form-data (a single "file" field) or a raw
+// application/octet-stream body paired with an X-Filename header.
+func (m *model) recvHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ip := m.resolveClientIP(r)
+
+	isAllowed, reason := m.checkAccess(ip)
+	if !isAllowed {
+		m.logActivity(activityLog{Timestamp: time.Now(), IP: ip, Action: fmt.Sprintf("Rejected: %s", reason), Style: styleIPRejected})
+		http.Error(w, reason, http.StatusForbidden)
+		return
+	}
+	m.logActivity(activityLog{Timestamp: time.Now(), IP: ip, Action: "Connected & Allowed", Style: styleIPAllowed})
+
+	src, filename, err := openUploadSource(r)
+	if err != nil {
+		m.logActivity(activityLog{Timestamp: time.Now(), IP: ip, Action: fmt.Sprintf("Error reading upload: %s", err), Style: styleError})
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if closer, ok := src.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	destPath := filepath.Join(m.outDir, filepath.Base(filename))
+	dest, err := os.Create(destPath)
+	if err != nil {
+		m.logActivity(activityLog{Timestamp: time.Now(), IP: ip, Action: fmt.Sprintf("Error creating %s: %s", destPath, err), Style: styleError})
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	defer dest.Close()
+
+	cw := &countingWriter{
+		w:            dest,
+		key:          ip + "+" + nextConnID(),
+		ip:           ip,
+		totalBytes:   r.ContentLength, // -1 (unknown) for chunked uploads; the bar then stays empty and the row reads "Complete" once done
+		startTime:    time.Now(),
+		progressChan: m.progressChan,
+	}
+
+	written, copyErr := io.Copy(cw, src)
+	m.progressChan <- progressMsg{key: cw.key, ip: ip, bytesWritten: written, totalBytes: cw.totalBytes, startTime: cw.startTime, done: true}
+
+	if copyErr != nil {
+		m.logActivity(activityLog{Timestamp: time.Now(), IP: ip, Action: fmt.Sprintf("Error during upload: %s", copyErr), Style: styleError})
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	m.logActivity(activityLog{Timestamp: time.Now(), IP: ip, Action: fmt.Sprintf("Upload Complete (%s -> %s)", formatBytes(written), filepath.Base(destPath)), Style: styleSuccess})
+	fmt.Fprintf(w, "Received %s (%s)\n", filepath.Base(destPath), formatBytes(written))
+}
+
+// openUploadSource picks apart the request to find the file being
+// uploaded, supporting both a multipart "file" field and a raw body with
+// Content-Type: application/octet-stream plus X-Filename.
+func openUploadSource(r *http.Request) (io.Reader, string, error) {
+	contentType := r.Header.Get("Content-Type")
+
+	if strings.HasPrefix(contentType, "multipart/form-data") {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", fmt.Errorf("expected a multipart 'file' field: %w", err)
+		}
+		return file, header.Filename, nil
+	}
+
+	filename := r.Header.Get("X-Filename")
+	if filename == "" {
+		return nil, "", fmt.Errorf("raw uploads require an X-Filename header (or use multipart/form-data)")
+	}
+	return r.Body, filename, nil
+}
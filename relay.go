@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/yamux"
+)
+
+// --- Public sharing over a vrushie-relay server ---
+//
+// When --public is set, vrushie keeps its direct LAN listener and also
+// dials the relay, receives a short-lived token identifying this session,
+// and multiplexes every HTTP request the relay forwards over a single yamux
+// client session. The relay is the only thing that needs a public IP;
+// vrushie itself can sit behind NAT with no port-forwarding.
+
+// relayHandshakeTimeout bounds how long we wait for the relay to hand us a token.
+const relayHandshakeTimeout = 10 * time.Second
+
+// relaySession bundles the yamux session with the token the relay assigned it.
+type relaySession struct {
+	conn    net.Conn
+	session *yamux.Session
+	token   string
+}
+
+// dialRelay connects to addr over TLS, performs the token handshake, and
+// opens a yamux client session on top of the connection. The relay is
+// expected to speak the trivial line protocol: after the TLS handshake it
+// writes "TOKEN <token>\n", then both sides switch to yamux framing.
+func dialRelay(addr string) (*relaySession, error) {
+	conn, err := tls.Dial("tcp", addr, &tls.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial relay %s: %w", addr, err)
+	}
+
+	conn.SetDeadline(time.Now().Add(relayHandshakeTimeout))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("relay handshake failed: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+
+	token := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "TOKEN"))
+	if token == "" {
+		conn.Close()
+		return nil, fmt.Errorf("relay handshake failed: no token in %q", line)
+	}
+
+	session, err := yamux.Client(conn, yamux.DefaultConfig())
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start yamux session: %w", err)
+	}
+
+	return &relaySession{conn: conn, session: session, token: token}, nil
+}
+
+// Close tears down the yamux session and the underlying relay connection.
+func (rs *relaySession) Close() error {
+	rs.session.Close()
+	return rs.conn.Close()
+}
+
+// yamuxStreamListener adapts a yamux.Session's incoming streams to the
+// net.Listener interface so the existing http.Server machinery (and
+// http.Serve) can consume them exactly like any other listener.
+type yamuxStreamListener struct {
+	session *yamux.Session
+}
+
+func (l *yamuxStreamListener) Accept() (net.Conn, error) {
+	return l.session.AcceptStream()
+}
+
+func (l *yamuxStreamListener) Close() error {
+	return l.session.Close()
+}
+
+func (l *yamuxStreamListener) Addr() net.Addr {
+	return l.session.Addr()
+}
+
+// publicURL builds the shareable URL a recipient off-LAN can open, given
+// the relay's public-facing host and the token assigned to this session.
+func publicURL(relayAddr, token string) string {
+	host := relayAddr
+	if h, _, err := net.SplitHostPort(relayAddr); err == nil {
+		host = h
+	}
+	return fmt.Sprintf("https://%s/s/%s/", host, token)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+)
+
+// selfSignedCertLifetime is deliberately short: these certificates exist
+// only to get a TLS tunnel up for the lifetime of a single share.
+const selfSignedCertLifetime = 24 * time.Hour
+
+// generateSelfSignedCert builds an in-memory ECDSA P-256 keypair and a
+// self-signed certificate covering every address in hosts (plus
+// "localhost", "127.0.0.1", and "::1", since the TUI always offers a
+// loopback URL alongside the LAN ones), valid for selfSignedCertLifetime.
+// Used when --tls-auto is set, or --tls is given with no --cert/--key.
+func generateSelfSignedCert(hosts []string) (tls.Certificate, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "vrushie self-signed"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(selfSignedCertLifetime),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	for _, h := range append(hosts, "localhost", "127.0.0.1", "::1") {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	// template.Raw is never populated (only x509.ParseCertificate sets it),
+	// so using &template as Leaf would make certFingerprint hash an empty
+	// byte slice for every cert. Parse the DER we just created instead.
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to parse generated certificate: %w", err)
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  priv,
+		Leaf:        leaf,
+	}, nil
+}
+
+// loadCertChain reads a possibly multi-certificate PEM file (leaf plus any
+// intermediates) and its key, walking pem.Decode in a loop the way most
+// chain-aware PEM parsers do, and returns a ready-to-use tls.Certificate.
+func loadCertChain(certFile, keyFile string) (tls.Certificate, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to read cert file: %w", err)
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var chain [][]byte
+	rest := certPEM
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			chain = append(chain, block.Bytes)
+		}
+	}
+	if len(chain) == 0 {
+		return tls.Certificate{}, fmt.Errorf("no CERTIFICATE blocks found in %s (empty or malformed chain)", certFile)
+	}
+
+	cert, err := tls.X509KeyPair(pemEncodeChain(chain), keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to parse cert/key pair: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to parse leaf certificate: %w", err)
+	}
+	cert.Leaf = leaf
+
+	return cert, nil
+}
+
+// pemEncodeChain re-serializes a slice of DER certificates back into a
+// single PEM blob so it can be handed to tls.X509KeyPair.
+func pemEncodeChain(chain [][]byte) []byte {
+	var out []byte
+	for _, der := range chain {
+		out = append(out, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	return out
+}
+
+// certFingerprint returns the SHA-256 fingerprint of a leaf certificate,
+// formatted as colon-separated hex, so a recipient can verify a
+// self-signed cert out-of-band before trusting the browser warning.
+func certFingerprint(leaf *x509.Certificate) string {
+	sum := sha256.Sum256(leaf.Raw)
+	var out string
+	for i, b := range sum {
+		if i > 0 {
+			out += ":"
+		}
+		out += fmt.Sprintf("%02X", b)
+	}
+	return out
+}